@@ -1,7 +1,8 @@
 /*
 Package Adafruit_CharLCD allows controlling the HD44780 character LCD
-controller when hooked up through an MCP23017 I2C-GPIO chip.
-Currently the library is write-only and does not support reading from the display controller.
+controller over any Controller transport: an MCP23017 or PCF8574 I2C-GPIO
+backpack, or GPIO pins wired up directly. Reading from the display controller
+is only supported when the underlying Controller's RW line is wired up.
 
 Resources
 
@@ -13,6 +14,7 @@ This library is based three other HD44780 libraries:
 package adafruit_charlcd
 
 import (
+	"io"
 	"time"
 
 	"github.com/golang/glog"
@@ -93,14 +95,17 @@ const (
 
 // ADAFRUIT_CHARLCD represents an HD44780-compatible character LCD controller.
 type ADAFRUIT_CHARLCD struct {
-	Connection
+	Controller
 	eMode   entryMode
 	dMode   displayMode
 	fMode   functionMode
 	rowAddr RowAddress
+	col     int
+	row     int
 }
 
-// NewI2C creates a new ADAFRUIT_CHARLCD connected by an I²C bus.
+// NewI2C creates a new ADAFRUIT_CHARLCD connected through an MCP23017 I²C-GPIO
+// backpack.
 func NewI2C(
 	i2c embd.I2CBus,
 	addr byte,
@@ -108,13 +113,34 @@ func NewI2C(
 	rowAddr RowAddress,
 	modes ...ModeSetter,
 ) (*ADAFRUIT_CHARLCD, error) {
-	return New(NewI2CConnection(i2c, addr, pinMap), rowAddr, modes...)
+	return New(NewMCP23017Controller(i2c, addr, pinMap), rowAddr, modes...)
 }
 
-// New creates a new ADAFRUIT_CHARLCD connected by a Connection bus.
-func New(bus Connection, rowAddr RowAddress, modes ...ModeSetter) (*ADAFRUIT_CHARLCD, error) {
+// NewPCF8574 creates a new ADAFRUIT_CHARLCD connected through a PCF8574/PCF8574A
+// I²C serial LCD backpack.
+func NewPCF8574(
+	i2c embd.I2CBus,
+	addr byte,
+	pinMap PCF8574PinMap,
+	rowAddr RowAddress,
+	modes ...ModeSetter,
+) (*ADAFRUIT_CHARLCD, error) {
+	return New(NewPCF8574Controller(i2c, addr, pinMap), rowAddr, modes...)
+}
+
+// NewGPIO creates a new ADAFRUIT_CHARLCD with RS/RW/EN/D0-D7 wired directly to GPIO.
+func NewGPIO(pins GPIOPinMap, rowAddr RowAddress, modes ...ModeSetter) (*ADAFRUIT_CHARLCD, error) {
+	controller, err := NewGPIOController(pins)
+	if err != nil {
+		return nil, err
+	}
+	return New(controller, rowAddr, modes...)
+}
+
+// New creates a new ADAFRUIT_CHARLCD driven by a Controller.
+func New(bus Controller, rowAddr RowAddress, modes ...ModeSetter) (*ADAFRUIT_CHARLCD, error) {
 	controller := &ADAFRUIT_CHARLCD{
-		Connection: bus,
+		Controller: bus,
 		eMode:      0x00,
 		dMode:      0x00,
 		fMode:      0x00,
@@ -132,6 +158,10 @@ func New(bus Connection, rowAddr RowAddress, modes ...ModeSetter) (*ADAFRUIT_CHA
 }
 
 func (controller *ADAFRUIT_CHARLCD) lcdInit() error {
+	if controller.Controller.EightBitMode() {
+		glog.V(2).Info("charlcd: initializing display in 8-bit mode")
+		return controller.WriteInstruction(lcdInit)
+	}
 	glog.V(2).Info("charlcd: initializing display")
 	err := controller.WriteInstruction(lcdInit)
 	if err != nil {
@@ -170,6 +200,18 @@ func EntryShiftOff(hd *ADAFRUIT_CHARLCD) { hd.eMode &= ^lcdEntryShiftOn }
 // EntryShiftOn is a ModeSetter that sets the ADAFRUIT_CHARLCD to entry shift on mode.
 func EntryShiftOn(hd *ADAFRUIT_CHARLCD) { hd.eMode |= lcdEntryShiftOn }
 
+// AutoscrollOff is a ModeSetter that disables automatic display scrolling as characters are written.
+func AutoscrollOff(hd *ADAFRUIT_CHARLCD) { hd.eMode &= ^lcdEntryShiftOn }
+
+// AutoscrollOn is a ModeSetter that enables automatic display scrolling as characters are written.
+func AutoscrollOn(hd *ADAFRUIT_CHARLCD) { hd.eMode |= lcdEntryShiftOn }
+
+// LeftToRight is a ModeSetter that sets the ADAFRUIT_CHARLCD to left-to-right text direction.
+func LeftToRight(hd *ADAFRUIT_CHARLCD) { hd.eMode |= lcdEntryIncrement }
+
+// RightToLeft is a ModeSetter that sets the ADAFRUIT_CHARLCD to right-to-left text direction.
+func RightToLeft(hd *ADAFRUIT_CHARLCD) { hd.eMode &= ^lcdEntryIncrement }
+
 // DisplayOff is a ModeSetter that sets the ADAFRUIT_CHARLCD to display off mode.
 func DisplayOff(hd *ADAFRUIT_CHARLCD) { hd.dMode &= ^lcdDisplayOn }
 
@@ -300,6 +342,30 @@ func (hd *ADAFRUIT_CHARLCD) BlinkOn() error {
 	return hd.setDisplayMode()
 }
 
+// AutoscrollOff disables automatic display scrolling as characters are written.
+func (hd *ADAFRUIT_CHARLCD) AutoscrollOff() error {
+	AutoscrollOff(hd)
+	return hd.setEntryMode()
+}
+
+// AutoscrollOn enables automatic display scrolling as characters are written.
+func (hd *ADAFRUIT_CHARLCD) AutoscrollOn() error {
+	AutoscrollOn(hd)
+	return hd.setEntryMode()
+}
+
+// LeftToRight sets the text entry direction to left-to-right.
+func (hd *ADAFRUIT_CHARLCD) LeftToRight() error {
+	LeftToRight(hd)
+	return hd.setEntryMode()
+}
+
+// RightToLeft sets the text entry direction to right-to-left.
+func (hd *ADAFRUIT_CHARLCD) RightToLeft() error {
+	RightToLeft(hd)
+	return hd.setEntryMode()
+}
+
 // ShiftLeft shifts the cursor and all characters to the left.
 func (hd *ADAFRUIT_CHARLCD) ShiftLeft() error {
 	return hd.WriteInstruction(lcdCursorShift | lcdDisplayMove | lcdMoveLeft)
@@ -310,10 +376,36 @@ func (hd *ADAFRUIT_CHARLCD) ShiftRight() error {
 	return hd.WriteInstruction(lcdCursorShift | lcdDisplayMove | lcdMoveRight)
 }
 
+// MoveCursorLeft moves the input cursor one position to the left without shifting
+// the display. hd.col is clamped at 0 rather than going negative.
+func (hd *ADAFRUIT_CHARLCD) MoveCursorLeft() error {
+	if err := hd.WriteInstruction(lcdCursorShift | lcdCursorMove | lcdMoveLeft); err != nil {
+		return err
+	}
+	if hd.col > 0 {
+		hd.col--
+	}
+	return nil
+}
+
+// MoveCursorRight moves the input cursor one position to the right without
+// shifting the display. hd.col is clamped at the last configured column rather
+// than running past it.
+func (hd *ADAFRUIT_CHARLCD) MoveCursorRight() error {
+	if err := hd.WriteInstruction(lcdCursorShift | lcdCursorMove | lcdMoveRight); err != nil {
+		return err
+	}
+	if cols := hd.cols(); cols <= 0 || hd.col < cols-1 {
+		hd.col++
+	}
+	return nil
+}
+
 // Home moves the cursor and all characters to the home position.
 func (hd *ADAFRUIT_CHARLCD) Home() error {
 	err := hd.WriteInstruction(lcdReturnHome)
 	time.Sleep(clearDelay)
+	hd.col, hd.row = 0, 0
 	return err
 }
 
@@ -324,12 +416,14 @@ func (hd *ADAFRUIT_CHARLCD) Clear() error {
 		return err
 	}
 	time.Sleep(clearDelay)
+	hd.col, hd.row = 0, 0
 	// have to set mode here because clear also clears some mode settings
 	return hd.SetMode()
 }
 
 // SetCursor sets the input cursor to the given position.
 func (hd *ADAFRUIT_CHARLCD) SetCursor(col, row int) error {
+	hd.col, hd.row = col, row
 	return hd.SetDDRamAddr(byte(col) + hd.lcdRowOffset(row))
 }
 
@@ -348,130 +442,111 @@ func (hd *ADAFRUIT_CHARLCD) SetDDRamAddr(value byte) error {
 
 // WriteInstruction writes a byte to the bus with register select in data mode.
 func (hd *ADAFRUIT_CHARLCD) WriteChar(value byte) error {
-	return hd.Write(true, value)
+	return hd.Controller.WriteData(value)
 }
 
 // WriteInstruction writes a byte to the bus with register select in command mode.
 func (hd *ADAFRUIT_CHARLCD) WriteInstruction(value byte) error {
-	return hd.Write(false, value)
+	return hd.Controller.WriteIR(value)
 }
 
-// Close closes the underlying Connection.
-func (hd *ADAFRUIT_CHARLCD) Close() error {
-	return hd.Connection.Close()
+// BacklightOff turns the optional backlight off.
+func (hd *ADAFRUIT_CHARLCD) BacklightOff() error {
+	return hd.Controller.SetBacklight(false)
 }
 
-// Connection abstracts the different methods of communicating with an ADAFRUIT_CHARLCD.
-type Connection interface {
-	// Write writes a byte to the ADAFRUIT_CHARLCD controller with the register select
-	// flag either on or off.
-	Write(rs bool, data byte) error
-
-	// BacklightOff turns the optional backlight off.
-	BacklightOff() error
-
-	// BacklightOn turns the optional backlight on.
-	BacklightOn() error
-
-	// Close closes all open resources.
-	Close() error
+// BacklightOn turns the optional backlight on.
+func (hd *ADAFRUIT_CHARLCD) BacklightOn() error {
+	return hd.Controller.SetBacklight(true)
 }
 
-// I2CConnection implements Connection using an I²C bus.
-type I2CConnection struct {
-	I2C       embd.I2CBus
-	Addr      byte
-	PinMap    I2CPinMap
-	Backlight bool
+// SetBacklightColor sets the red, green, and blue channels of the backlight on a
+// Controller with an RGB backlight (RGBBacklightController). On a Controller with
+// only a single-color backlight, any of r, g, b being true turns it on, and all
+// false turns it off.
+func (hd *ADAFRUIT_CHARLCD) SetBacklightColor(r, g, b bool) error {
+	if rgb, ok := hd.Controller.(RGBBacklightController); ok {
+		return rgb.SetBacklightColor(r, g, b)
+	}
+	return hd.Controller.SetBacklight(r || g || b)
 }
 
-// I2CPinMap represents a mapping between the pins on an I²C port expander and
-// the pins on the ADAFRUIT_CHARLCD controller.
-type I2CPinMap struct {
-	RS, RW, EN     byte
-	D4, D5, D6, D7 byte
-	Backlight      byte
-	BLPolarity     BacklightPolarity
+// ReadButtons returns a bitmask of the buttons currently pressed, on a Controller
+// with buttons wired up (ButtonController). It always returns a zero mask on a
+// Controller without buttons.
+func (hd *ADAFRUIT_CHARLCD) ReadButtons() (ButtonMask, error) {
+	if btn, ok := hd.Controller.(ButtonController); ok {
+		return btn.ReadButtons()
+	}
+	return 0, nil
 }
 
-var (
-	// MXXXXXPinMap is the standard pin mapping for a PCF8574-based I²C backpack.
-	MCP230XXPinMap I2CPinMap = I2CPinMap{
-		RS: 7, RW: 6, EN: 5,
-		D4: 4, D5: 3, D6: 2, D7: 1,
-		Backlight:  0,
-		BLPolarity: Positive,
+// CreateChar defines one of the 8 CGRAM custom characters (location 0-7) with the
+// given bitmap, one byte of the lower 5 bits per row, top row first. location is
+// masked to its low 3 bits (0-7), so 9 selects location 1. DDRAM addressing is restored afterwards so subsequent writes
+// resume at the home position instead of CGRAM.
+func (hd *ADAFRUIT_CHARLCD) CreateChar(location byte, bitmap [8]byte) error {
+	location &= 0x07
+	if err := hd.WriteInstruction(lcdSetCGRamAddr | (location << 3)); err != nil {
+		return err
 	}
-)
-
-// NewI2CConnection returns a new Connection based on an I²C bus.
-func NewI2CConnection(i2c embd.I2CBus, addr byte, pinMap I2CPinMap) *I2CConnection {
-	x := &I2CConnection{
-		I2C:    i2c,
-		Addr:   addr,
-		PinMap: pinMap,
+	for _, row := range bitmap {
+		if err := hd.WriteChar(row); err != nil {
+			return err
+		}
 	}
-	_ = x.I2C.WriteByteToReg(addr, 0x05, 0x00) // IOCON.BANK = 0 if it was in BANK1 mode
-	_ = x.I2C.WriteByteToReg(addr, 0x0A, 0x20) // IOCON.BANK = 0, no auto-increment
-	_ = x.I2C.WriteByteToReg(addr, 0x12, 0x40) // backlight off
-	_ = x.I2C.WriteByteToReg(addr, 0x00, 0x00) // all A-pins to output
-	_ = x.I2C.WriteByteToReg(addr, 0x01, 0x00) // all B-pins to output
-	return x
+	return hd.SetCursor(0, 0)
 }
 
-// BacklightOff turns the optional backlight off.
-func (conn *I2CConnection) BacklightOff() error {
-	return conn.I2C.WriteByteToReg(conn.Addr, 0x12, 0x40) // backlight off
+// cols returns the configured display width. RowAddress16Col and RowAddress20Col
+// both set the third row's DDRAM offset equal to the column count, so it doubles
+// as the wrap width for Write.
+func (hd *ADAFRUIT_CHARLCD) cols() int {
+	return int(hd.rowAddr[2])
 }
 
-// BacklightOn turns the optional backlight on.
-func (conn *I2CConnection) BacklightOn() error {
-	return conn.I2C.WriteByteToReg(conn.Addr, 0x12, 0x00) // backlight on
-}
-
-// Write writes a register select flag and byte to the I²C connection.
-func (conn *I2CConnection) Write(rs bool, data byte) error {
-	var instructionHigh byte = 0x00
-	instructionHigh |= ((data >> 4) & 0x01) << conn.PinMap.D4
-	instructionHigh |= ((data >> 5) & 0x01) << conn.PinMap.D5
-	instructionHigh |= ((data >> 6) & 0x01) << conn.PinMap.D6
-	instructionHigh |= ((data >> 7) & 0x01) << conn.PinMap.D7
-
-	var instructionLow byte = 0x00
-	instructionLow |= (data & 0x01) << conn.PinMap.D4
-	instructionLow |= ((data >> 1) & 0x01) << conn.PinMap.D5
-	instructionLow |= ((data >> 2) & 0x01) << conn.PinMap.D6
-	instructionLow |= ((data >> 3) & 0x01) << conn.PinMap.D7
-
-	instructions := []byte{instructionHigh, instructionLow}
-	for _, ins := range instructions {
-		if rs {
-			ins |= 0x01 << conn.PinMap.RS
+// numRows returns the number of rows Write wraps text across: 1 if the display
+// was configured with OneLine, 2 otherwise. The HD44780 function-set register
+// only distinguishes 1-line from 2-line mode; 4-row modules also run in 2-line
+// mode and address their extra rows directly through SetCursor rather than
+// Write's automatic wrap.
+func (hd *ADAFRUIT_CHARLCD) numRows() int {
+	if hd.TwoLineEnabled() {
+		return 2
+	}
+	return 1
+}
+
+// Write implements io.Writer so text can be sent to the display with
+// fmt.Fprintf(lcd, ...). '\n' moves to the start of the next row; text also wraps
+// to the next row once it reaches the configured column width. Once the last
+// configured row is passed, it wraps back around to row 0.
+func (hd *ADAFRUIT_CHARLCD) Write(p []byte) (int, error) {
+	cols := hd.cols()
+	rows := hd.numRows()
+	for i, b := range p {
+		if b == '\n' {
+			if err := hd.SetCursor(0, (hd.row+1)%rows); err != nil {
+				return i, err
+			}
+			continue
 		}
-		glog.V(3).Infof("charlcd: writing to I2C: %#x", ins)
-		err := conn.pulseEnable(ins)
-		if err != nil {
-			return err
+		if cols > 0 && hd.col >= cols {
+			if err := hd.SetCursor(0, (hd.row+1)%rows); err != nil {
+				return i, err
+			}
 		}
-	}
-	time.Sleep(writeDelay)
-	return nil
-}
-
-func (conn *I2CConnection) pulseEnable(data byte) error {
-	bytes := []byte{data, data | (0x01 << conn.PinMap.EN), data}
-	for _, b := range bytes {
-		time.Sleep(pulseDelay)
-		err := conn.I2C.WriteByteToReg(conn.Addr, 0x13, b)
-		if err != nil {
-			return err
+		if err := hd.WriteChar(b); err != nil {
+			return i, err
 		}
+		hd.col++
 	}
-	return nil
+	return len(p), nil
 }
 
-// Close closes the I²C connection.
-func (conn *I2CConnection) Close() error {
-	glog.V(2).Info("charlcd: closing I2C bus")
-	return conn.I2C.Close()
+var _ io.Writer = (*ADAFRUIT_CHARLCD)(nil)
+
+// Close closes the underlying Controller.
+func (hd *ADAFRUIT_CHARLCD) Close() error {
+	return hd.Controller.Close()
 }
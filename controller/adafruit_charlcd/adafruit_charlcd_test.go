@@ -0,0 +1,126 @@
+package adafruit_charlcd
+
+import "testing"
+
+// fakeController is a minimal Controller stand-in recording every
+// WriteIR/WriteData call so ADAFRUIT_CHARLCD's CGRAM addressing, mode bits,
+// and Write/cursor logic can be checked without real hardware.
+type fakeController struct {
+	ir, data []byte
+	eightBit bool
+}
+
+func (c *fakeController) WriteIR(value byte) error   { c.ir = append(c.ir, value); return nil }
+func (c *fakeController) WriteData(value byte) error { c.data = append(c.data, value); return nil }
+func (c *fakeController) ReadIR() (byte, error)      { return 0, errReadNotSupported }
+func (c *fakeController) ReadData() (byte, error)    { return 0, errReadNotSupported }
+func (c *fakeController) SetBacklight(on bool) error { return nil }
+func (c *fakeController) EightBitMode() bool         { return c.eightBit }
+func (c *fakeController) Close() error               { return nil }
+
+func newTestLCD(t *testing.T, rowAddr RowAddress, modes ...ModeSetter) (*ADAFRUIT_CHARLCD, *fakeController) {
+	t.Helper()
+	fc := &fakeController{}
+	hd, err := New(fc, rowAddr, modes...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	fc.ir, fc.data = nil, nil // drop the init-time writes New issues
+	return hd, fc
+}
+
+// TestCreateCharAddressingAndRestore checks that CreateChar masks the CGRAM
+// location to 0-7, writes the bitmap via WriteData, and restores DDRAM
+// addressing (and hd.col/hd.row) to the home position afterwards.
+func TestCreateCharAddressingAndRestore(t *testing.T) {
+	hd, fc := newTestLCD(t, RowAddress16Col)
+	hd.col, hd.row = 5, 1 // simulate a prior write leaving the cursor elsewhere
+
+	bitmap := [8]byte{0x1F, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x1F, 0x00}
+	if err := hd.CreateChar(9, bitmap); err != nil { // 9 & 0x07 == 1
+		t.Fatalf("CreateChar: %v", err)
+	}
+
+	wantIR := []byte{lcdSetCGRamAddr | (1 << 3), lcdSetDDRamAddr | 0}
+	if !bytesEqual(fc.ir, wantIR) {
+		t.Errorf("IR writes = %#v, want %#v", fc.ir, wantIR)
+	}
+	if !bytesEqual(fc.data, bitmap[:]) {
+		t.Errorf("data writes = %#v, want %#v", fc.data, bitmap[:])
+	}
+	if hd.col != 0 || hd.row != 0 {
+		t.Errorf("hd.col, hd.row = %d, %d, want 0, 0", hd.col, hd.row)
+	}
+}
+
+// TestAutoscrollAndDirectionModeBits checks that the Autoscroll and
+// LeftToRight/RightToLeft ModeSetters flip the expected lcdEntry* bits.
+func TestAutoscrollAndDirectionModeBits(t *testing.T) {
+	hd, _ := newTestLCD(t, RowAddress16Col, AutoscrollOff, LeftToRight)
+	if hd.EntryShiftEnabled() {
+		t.Error("AutoscrollOff: EntryShiftEnabled() = true, want false")
+	}
+	if !hd.EntryIncrementEnabled() {
+		t.Error("LeftToRight: EntryIncrementEnabled() = false, want true")
+	}
+
+	if err := hd.SetMode(AutoscrollOn, RightToLeft); err != nil {
+		t.Fatalf("SetMode: %v", err)
+	}
+	if !hd.EntryShiftEnabled() {
+		t.Error("AutoscrollOn: EntryShiftEnabled() = false, want true")
+	}
+	if hd.EntryIncrementEnabled() {
+		t.Error("RightToLeft: EntryIncrementEnabled() = true, want false")
+	}
+}
+
+// TestMoveCursorClampsAtEdges checks that MoveCursorLeft/MoveCursorRight clamp
+// hd.col at the display edges instead of running negative or past the
+// configured column width.
+func TestMoveCursorClampsAtEdges(t *testing.T) {
+	hd, _ := newTestLCD(t, RowAddress16Col)
+
+	hd.col = 0
+	if err := hd.MoveCursorLeft(); err != nil {
+		t.Fatalf("MoveCursorLeft: %v", err)
+	}
+	if hd.col != 0 {
+		t.Errorf("hd.col after MoveCursorLeft at 0 = %d, want 0", hd.col)
+	}
+
+	hd.col = hd.cols() - 1
+	if err := hd.MoveCursorRight(); err != nil {
+		t.Fatalf("MoveCursorRight: %v", err)
+	}
+	if hd.col != hd.cols()-1 {
+		t.Errorf("hd.col after MoveCursorRight at last column = %d, want %d", hd.col, hd.cols()-1)
+	}
+}
+
+// TestWriteWrapsRowsWithinNumRows checks that a '\n' passed to Write
+// cycles back to row 0 after the last configured row instead of growing
+// hd.row without bound.
+func TestWriteWrapsRowsWithinNumRows(t *testing.T) {
+	hd, _ := newTestLCD(t, RowAddress16Col, TwoLine) // numRows() == 2
+	if _, err := hd.Write([]byte("A\nB\nC")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if hd.row != 0 || hd.col != 1 {
+		t.Errorf("after wrapping past row 1: hd.row, hd.col = %d, %d, want 0, 1", hd.row, hd.col)
+	}
+}
+
+// TestWriteColumnWrapStaysWithinNumRows checks that wrapping on column
+// overflow also respects numRows(), rather than growing hd.row unbounded on a
+// OneLine display.
+func TestWriteColumnWrapStaysWithinNumRows(t *testing.T) {
+	narrowRowAddr := RowAddress{0x00, 0x40, 0x02, 0x42} // cols() == 2
+	hd, _ := newTestLCD(t, narrowRowAddr, OneLine)      // numRows() == 1
+	if _, err := hd.Write([]byte("abc")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if hd.row != 0 || hd.col != 1 {
+		t.Errorf("after column-wrap on a 1-line display: hd.row, hd.col = %d, %d, want 0, 1", hd.row, hd.col)
+	}
+}
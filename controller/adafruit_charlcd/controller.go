@@ -0,0 +1,65 @@
+package adafruit_charlcd
+
+// Controller is the low-level interface to an HD44780-compatible character LCD,
+// independent of how its RS/RW/EN/D0-D7 lines are attached. ADAFRUIT_CHARLCD only
+// depends on Controller, so any transport (I2C port expander, direct GPIO, or a
+// future transport) can drive the same high-level API.
+type Controller interface {
+	// WriteIR writes a byte to the instruction register (RS held low).
+	WriteIR(value byte) error
+
+	// WriteData writes a byte to the data register (RS held high).
+	WriteData(value byte) error
+
+	// ReadIR reads the instruction register (RS low, RW high), returning the busy
+	// flag in bit 7 and the address counter in bits 0-6. It returns an error if
+	// the controller's RW line isn't wired up for reading.
+	ReadIR() (byte, error)
+
+	// ReadData reads the data register (RS high, RW high). It returns an error if
+	// the controller's RW line isn't wired up for reading.
+	ReadData() (byte, error)
+
+	// SetBacklight turns the backlight on or off.
+	SetBacklight(on bool) error
+
+	// EightBitMode reports whether the controller is wired for 8-bit transfers. A
+	// false result means data is transferred as two 4-bit nibbles, high nibble first.
+	EightBitMode() bool
+
+	// Close releases the underlying transport.
+	Close() error
+}
+
+// RGBBacklightController is implemented by Controllers with a 3-channel RGB
+// backlight, such as Adafruit's RGB LCD Shield. ADAFRUIT_CHARLCD.SetBacklightColor
+// uses this when the underlying Controller supports it.
+type RGBBacklightController interface {
+	SetBacklightColor(r, g, b bool) error
+}
+
+// ButtonController is implemented by Controllers with buttons wired up, such as
+// Adafruit's RGB LCD Shield. ADAFRUIT_CHARLCD.ReadButtons uses this when the
+// underlying Controller supports it.
+type ButtonController interface {
+	ReadButtons() (ButtonMask, error)
+}
+
+// ButtonMask is a bitmask of the momentary pushbuttons on Adafruit's RGB LCD
+// Shield, as returned by ButtonController.ReadButtons.
+type ButtonMask byte
+
+const (
+	// ButtonSelect indicates the Select button is pressed.
+	ButtonSelect ButtonMask = 0x01
+	// ButtonRight indicates the Right button is pressed.
+	ButtonRight ButtonMask = 0x02
+	// ButtonDown indicates the Down button is pressed.
+	ButtonDown ButtonMask = 0x04
+	// ButtonUp indicates the Up button is pressed.
+	ButtonUp ButtonMask = 0x08
+	// ButtonLeft indicates the Left button is pressed.
+	ButtonLeft ButtonMask = 0x10
+
+	buttonMaskAll ButtonMask = ButtonSelect | ButtonRight | ButtonDown | ButtonUp | ButtonLeft
+)
@@ -0,0 +1,198 @@
+package adafruit_charlcd
+
+import "strconv"
+
+// escapeState tracks progress through an in-flight \e[L...; escape sequence so
+// EscapeWriter.Write can resume correctly when a sequence straddles two calls.
+type escapeState int
+
+const (
+	escNone    escapeState = iota // not in an escape sequence
+	escESC                        // saw \e
+	escBracket                    // saw \e[
+	escL                          // saw \e[L
+	escCmd                        // saw \e[L<cmd>, collecting parameters up to ';'
+)
+
+// EscapeWriter wraps an ADAFRUIT_CHARLCD and implements io.Writer, interpreting
+// the same terminal-style control sequences the Linux auxdisplay/charlcd core
+// exposes to userspace, so higher-level code can drive the display with a single
+// byte stream instead of calling ADAFRUIT_CHARLCD's methods directly.
+//
+// Recognized sequences: \b (back-cursor + erase), \n (start of next line,
+// clearing to end of the current one), \r (carriage return), \f (clear + home),
+// and \e[L<cmd>;-terminated sequences: Lc±/Ld±/Lb± cursor/display/blink on or
+// off, L+/L- backlight on or off, Lx<n>/Ly<n> absolute column/row, Lk<n>
+// erase-n-chars, Lg<loc><16 hex digits> define a custom character via
+// CreateChar, and L* clear-and-home.
+type EscapeWriter struct {
+	lcd *ADAFRUIT_CHARLCD
+
+	state escapeState
+	cmd   byte
+	param []byte
+}
+
+// NewEscapeWriter returns a new EscapeWriter driving lcd.
+func NewEscapeWriter(lcd *ADAFRUIT_CHARLCD) *EscapeWriter {
+	return &EscapeWriter{lcd: lcd}
+}
+
+// Write implements io.Writer. The escape state machine is parsed incrementally,
+// one byte at a time, so a sequence may straddle two Write calls.
+func (w *EscapeWriter) Write(p []byte) (int, error) {
+	for i, b := range p {
+		if err := w.feed(b); err != nil {
+			return i, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *EscapeWriter) feed(b byte) error {
+	switch w.state {
+	case escESC:
+		if b == '[' {
+			w.state = escBracket
+			return nil
+		}
+		w.state = escNone
+		return w.writeLiteral(b)
+	case escBracket:
+		if b == 'L' {
+			w.state = escL
+			return nil
+		}
+		w.state = escNone
+		return w.writeLiteral(b)
+	case escL:
+		w.cmd = b
+		w.param = w.param[:0]
+		w.state = escCmd
+		return nil
+	case escCmd:
+		if b == ';' {
+			w.state = escNone
+			return w.execute(w.cmd, w.param)
+		}
+		w.param = append(w.param, b)
+		return nil
+	}
+
+	if b == 0x1b {
+		w.state = escESC
+		return nil
+	}
+	return w.writeLiteral(b)
+}
+
+func (w *EscapeWriter) writeLiteral(b byte) error {
+	switch b {
+	case '\b':
+		if err := w.lcd.MoveCursorLeft(); err != nil {
+			return err
+		}
+		if _, err := w.lcd.Write([]byte{' '}); err != nil {
+			return err
+		}
+		return w.lcd.MoveCursorLeft()
+	case '\n':
+		return w.eraseToEndOfLineAndAdvance()
+	case '\r':
+		return w.lcd.SetCursor(0, w.lcd.row)
+	case '\f':
+		return w.lcd.Clear()
+	default:
+		_, err := w.lcd.Write([]byte{b})
+		return err
+	}
+}
+
+// eraseToEndOfLineAndAdvance clears the rest of the current row and moves to the
+// start of the next one, per \n in the Linux charlcd protocol.
+func (w *EscapeWriter) eraseToEndOfLineAndAdvance() error {
+	cols := w.lcd.cols()
+	row := w.lcd.row
+	for cols > 0 && w.lcd.col < cols {
+		if _, err := w.lcd.Write([]byte{' '}); err != nil {
+			return err
+		}
+	}
+	return w.lcd.SetCursor(0, row+1)
+}
+
+func (w *EscapeWriter) execute(cmd byte, param []byte) error {
+	switch cmd {
+	case 'c':
+		return w.applySign(param, w.lcd.CursorOn, w.lcd.CursorOff)
+	case 'd':
+		return w.applySign(param, w.lcd.DisplayOn, w.lcd.DisplayOff)
+	case 'b':
+		return w.applySign(param, w.lcd.BlinkOn, w.lcd.BlinkOff)
+	case '+':
+		return w.lcd.BacklightOn()
+	case '-':
+		return w.lcd.BacklightOff()
+	case 'x':
+		n, err := parseUint(param)
+		if err != nil {
+			return nil
+		}
+		return w.lcd.SetCursor(n, w.lcd.row)
+	case 'y':
+		n, err := parseUint(param)
+		if err != nil {
+			return nil
+		}
+		return w.lcd.SetCursor(w.lcd.col, n)
+	case 'k':
+		n, err := parseUint(param)
+		if err != nil {
+			return nil
+		}
+		for i := 0; i < n; i++ {
+			if _, err := w.lcd.Write([]byte{' '}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case 'g':
+		return w.defineChar(param)
+	case '*':
+		return w.lcd.Clear()
+	}
+	// Unrecognized command: ignore, matching the kernel driver's leniency.
+	return nil
+}
+
+func (w *EscapeWriter) applySign(param []byte, on, off func() error) error {
+	if len(param) == 0 || param[0] != '-' {
+		return on()
+	}
+	return off()
+}
+
+// defineChar parses "<loc><16 hex digits>" (one hex digit CGRAM location
+// followed by 8 hex-encoded bitmap bytes) and defines it via CreateChar.
+func (w *EscapeWriter) defineChar(param []byte) error {
+	if len(param) < 1+16 {
+		return nil
+	}
+	loc, err := strconv.ParseUint(string(param[0:1]), 16, 8)
+	if err != nil {
+		return nil
+	}
+	var bitmap [8]byte
+	for i := range bitmap {
+		v, err := strconv.ParseUint(string(param[1+i*2:3+i*2]), 16, 8)
+		if err != nil {
+			return nil
+		}
+		bitmap[i] = byte(v)
+	}
+	return w.lcd.CreateChar(byte(loc), bitmap)
+}
+
+func parseUint(param []byte) (int, error) {
+	return strconv.Atoi(string(param))
+}
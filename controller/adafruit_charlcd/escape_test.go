@@ -0,0 +1,86 @@
+package adafruit_charlcd
+
+import "testing"
+
+// TestEscapeWriterLiteralAndCursorCommands checks plain text, absolute
+// cursor positioning (Lx/Ly), and backlight on/off sequences.
+func TestEscapeWriterLiteralAndCursorCommands(t *testing.T) {
+	hd, fc := newTestLCD(t, RowAddress16Col)
+	w := NewEscapeWriter(hd)
+
+	if _, err := w.Write([]byte("hi\x1b[Lx5;\x1b[Ly1;\x1b[L+;")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if !bytesEqual(fc.data[:2], []byte("hi")) {
+		t.Errorf("data writes = %#v, want first two bytes \"hi\"", fc.data)
+	}
+	if hd.col != 5 || hd.row != 1 {
+		t.Errorf("hd.col, hd.row = %d, %d, want 5, 1", hd.col, hd.row)
+	}
+}
+
+// TestEscapeWriterStraddlesWriteCalls checks that an escape sequence split
+// across two Write calls is still parsed correctly.
+func TestEscapeWriterStraddlesWriteCalls(t *testing.T) {
+	hd, _ := newTestLCD(t, RowAddress16Col)
+	w := NewEscapeWriter(hd)
+
+	if _, err := w.Write([]byte("\x1b[Lx")); err != nil {
+		t.Fatalf("Write (first half): %v", err)
+	}
+	if _, err := w.Write([]byte("7;")); err != nil {
+		t.Fatalf("Write (second half): %v", err)
+	}
+
+	if hd.col != 7 || hd.row != 0 {
+		t.Errorf("hd.col, hd.row = %d, %d, want 7, 0", hd.col, hd.row)
+	}
+}
+
+// TestEscapeWriterDefineChar checks that Lg<loc><16 hex digits> decodes the
+// bitmap and defines it via CreateChar.
+func TestEscapeWriterDefineChar(t *testing.T) {
+	hd, fc := newTestLCD(t, RowAddress16Col)
+	w := NewEscapeWriter(hd)
+
+	if _, err := w.Write([]byte("\x1b[Lg21F11111F11111F00;")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	wantIR := []byte{lcdSetCGRamAddr | (2 << 3), lcdSetDDRamAddr | 0}
+	if !bytesEqual(fc.ir, wantIR) {
+		t.Errorf("IR writes = %#v, want %#v", fc.ir, wantIR)
+	}
+	wantBitmap := []byte{0x1F, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x1F, 0x00}
+	if !bytesEqual(fc.data, wantBitmap) {
+		t.Errorf("data writes = %#v, want %#v", fc.data, wantBitmap)
+	}
+}
+
+// TestEscapeWriterBackspace checks that \b moves left, erases with a space,
+// and moves left again, clamped at column 0 rather than going negative.
+func TestEscapeWriterBackspace(t *testing.T) {
+	hd, fc := newTestLCD(t, RowAddress16Col)
+	w := NewEscapeWriter(hd)
+
+	if _, err := w.Write([]byte("A\b")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	wantData := []byte{'A', ' '}
+	if !bytesEqual(fc.data, wantData) {
+		t.Errorf("data writes = %#v, want %#v", fc.data, wantData)
+	}
+	if hd.col != 0 {
+		t.Errorf("hd.col = %d, want 0", hd.col)
+	}
+
+	fc.data = nil
+	if _, err := w.Write([]byte{'\b'}); err != nil {
+		t.Fatalf("Write at column 0: %v", err)
+	}
+	if hd.col != 0 {
+		t.Errorf("hd.col after backspace at column 0 = %d, want 0", hd.col)
+	}
+}
@@ -0,0 +1,246 @@
+package adafruit_charlcd
+
+import (
+	"time"
+
+	"github.com/jjcinaz/embd"
+)
+
+// GPIOPinMap describes the embd.DigitalPins used to drive an HD44780 controller
+// wired directly to GPIO, without an I²C port expander.
+type GPIOPinMap struct {
+	RS, EN embd.DigitalPin
+	RW     embd.DigitalPin // optional; leave nil if RW is tied to ground (write-only)
+
+	// D holds the data lines. In 4-bit mode only D[4:8] (D4-D7) need be set; in
+	// 8-bit mode all of D[0:8] must be set.
+	D [8]embd.DigitalPin
+
+	Backlight  embd.DigitalPin // optional
+	BLPolarity BacklightPolarity
+	EightBit   bool
+}
+
+// GPIOController implements Controller by driving RS/RW/EN/D0-D7 directly through
+// embd.DigitalPins, mirroring the 4-bit and 8-bit write paths in the reference
+// LiquidCrystal library.
+type GPIOController struct {
+	Pins GPIOPinMap
+}
+
+// NewGPIOController returns a new Controller that drives an HD44780 LCD directly
+// through GPIO, configuring the pin directions needed for writing (and, when RW
+// is set, for busy-flag polling reads).
+func NewGPIOController(pins GPIOPinMap) (*GPIOController, error) {
+	c := &GPIOController{Pins: pins}
+	outputs := append([]embd.DigitalPin{c.Pins.RS, c.Pins.EN}, c.dataPins()...)
+	for _, pin := range outputs {
+		if err := pin.SetDirection(embd.Out); err != nil {
+			return nil, err
+		}
+	}
+	if c.Pins.RW != nil {
+		if err := c.Pins.RW.SetDirection(embd.Out); err != nil {
+			return nil, err
+		}
+		if err := c.Pins.RW.Write(0); err != nil {
+			return nil, err
+		}
+	}
+	if c.Pins.Backlight != nil {
+		if err := c.Pins.Backlight.SetDirection(embd.Out); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.Pins.EN.Write(0); err != nil {
+		return nil, err
+	}
+	if err := c.SetBacklight(false); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// dataPins returns the data lines in use: all 8 in 8-bit mode, D4-D7 in 4-bit mode.
+func (c *GPIOController) dataPins() []embd.DigitalPin {
+	if c.Pins.EightBit {
+		return c.Pins.D[:]
+	}
+	return c.Pins.D[4:]
+}
+
+// EightBitMode reports whether the controller is wired for 8-bit transfers.
+func (c *GPIOController) EightBitMode() bool { return c.Pins.EightBit }
+
+// SetBacklight turns the optional backlight on or off. It is a no-op if no
+// backlight pin is wired up.
+func (c *GPIOController) SetBacklight(on bool) error {
+	if c.Pins.Backlight == nil {
+		return nil
+	}
+	if on == (c.Pins.BLPolarity == Positive) {
+		return c.Pins.Backlight.Write(1)
+	}
+	return c.Pins.Backlight.Write(0)
+}
+
+// WriteIR writes an instruction byte (RS low) to the display.
+func (c *GPIOController) WriteIR(value byte) error {
+	return c.send(false, value)
+}
+
+// WriteData writes a data byte (RS high) to the display.
+func (c *GPIOController) WriteData(value byte) error {
+	return c.send(true, value)
+}
+
+func (c *GPIOController) send(rs bool, value byte) error {
+	if err := c.waitWhileBusy(); err != nil {
+		return err
+	}
+	if c.Pins.RW != nil {
+		if err := c.Pins.RW.Write(0); err != nil {
+			return err
+		}
+	}
+	if err := c.setRS(rs); err != nil {
+		return err
+	}
+	if c.Pins.EightBit {
+		return c.pulse(value)
+	}
+	if err := c.pulse(value >> 4); err != nil {
+		return err
+	}
+	return c.pulse(value)
+}
+
+func (c *GPIOController) setRS(rs bool) error {
+	if rs {
+		return c.Pins.RS.Write(1)
+	}
+	return c.Pins.RS.Write(0)
+}
+
+// pulse writes the low nibble (or full byte, in 8-bit mode) of value to the data
+// pins and strobes EN.
+func (c *GPIOController) pulse(value byte) error {
+	for i, pin := range c.dataPins() {
+		if err := pin.Write(int((value >> uint(i)) & 0x01)); err != nil {
+			return err
+		}
+	}
+	if err := c.Pins.EN.Write(1); err != nil {
+		return err
+	}
+	time.Sleep(pulseDelay)
+	if err := c.Pins.EN.Write(0); err != nil {
+		return err
+	}
+	time.Sleep(pulseDelay)
+	return nil
+}
+
+// ReadIR reads the instruction register, returning the busy flag in bit 7 and the
+// address counter in bits 0-6. It returns an error if RW isn't wired up.
+func (c *GPIOController) ReadIR() (byte, error) {
+	return c.read(false)
+}
+
+// ReadData reads the data register. It returns an error if RW isn't wired up.
+func (c *GPIOController) ReadData() (byte, error) {
+	return c.read(true)
+}
+
+func (c *GPIOController) read(rs bool) (byte, error) {
+	if c.Pins.RW == nil {
+		return 0, errReadNotSupported
+	}
+	if err := c.setRS(rs); err != nil {
+		return 0, err
+	}
+	if err := c.Pins.RW.Write(1); err != nil {
+		return 0, err
+	}
+	pins := c.dataPins()
+	for _, pin := range pins {
+		if err := pin.SetDirection(embd.In); err != nil {
+			return 0, err
+		}
+	}
+	defer func() {
+		for _, pin := range pins {
+			_ = pin.SetDirection(embd.Out)
+		}
+		_ = c.Pins.RW.Write(0)
+	}()
+
+	if !c.Pins.EightBit {
+		high, err := c.readPins(pins)
+		if err != nil {
+			return 0, err
+		}
+		low, err := c.readPins(pins)
+		if err != nil {
+			return 0, err
+		}
+		return high<<4 | low, nil
+	}
+	return c.readPins(pins)
+}
+
+func (c *GPIOController) readPins(pins []embd.DigitalPin) (byte, error) {
+	if err := c.Pins.EN.Write(1); err != nil {
+		return 0, err
+	}
+	time.Sleep(pulseDelay)
+	var value byte
+	for i, pin := range pins {
+		v, err := pin.Read()
+		if err != nil {
+			return 0, err
+		}
+		value |= byte(v&0x01) << uint(i)
+	}
+	if err := c.Pins.EN.Write(0); err != nil {
+		return 0, err
+	}
+	time.Sleep(pulseDelay)
+	return value, nil
+}
+
+// waitWhileBusy polls the busy flag via ReadIR when RW is wired up, replacing the
+// fixed write delay the other Controllers rely on; otherwise it falls back to
+// sleeping for writeDelay.
+func (c *GPIOController) waitWhileBusy() error {
+	if c.Pins.RW == nil {
+		time.Sleep(writeDelay)
+		return nil
+	}
+	for {
+		ir, err := c.ReadIR()
+		if err != nil {
+			return err
+		}
+		if ir&0x80 == 0 {
+			return nil
+		}
+	}
+}
+
+// Close releases the GPIO pins used by the controller.
+func (c *GPIOController) Close() error {
+	pins := append([]embd.DigitalPin{c.Pins.RS, c.Pins.EN}, c.dataPins()...)
+	if c.Pins.RW != nil {
+		pins = append(pins, c.Pins.RW)
+	}
+	if c.Pins.Backlight != nil {
+		pins = append(pins, c.Pins.Backlight)
+	}
+	for _, pin := range pins {
+		if err := pin.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package adafruit_charlcd
+
+import (
+	"testing"
+
+	"github.com/jjcinaz/embd"
+)
+
+// fakeDigitalPin is a minimal embd.DigitalPin stand-in recording every Write
+// so GPIOController's 4-bit nibble packing in pulse() can be checked without
+// real hardware.
+type fakeDigitalPin struct {
+	dir    embd.Direction
+	writes []int
+	level  int
+}
+
+func (p *fakeDigitalPin) SetDirection(dir embd.Direction) error {
+	p.dir = dir
+	return nil
+}
+
+func (p *fakeDigitalPin) Write(val int) error {
+	p.writes = append(p.writes, val)
+	return nil
+}
+
+func (p *fakeDigitalPin) Read() (int, error) { return p.level, nil }
+
+func (p *fakeDigitalPin) Close() error { return nil }
+
+func intsEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func newFakeGPIOController(t *testing.T) (*GPIOController, map[string]*fakeDigitalPin) {
+	t.Helper()
+	pins := map[string]*fakeDigitalPin{
+		"RS": {}, "EN": {}, "D4": {}, "D5": {}, "D6": {}, "D7": {},
+	}
+	pinMap := GPIOPinMap{
+		RS: pins["RS"],
+		EN: pins["EN"],
+	}
+	pinMap.D[4] = pins["D4"]
+	pinMap.D[5] = pins["D5"]
+	pinMap.D[6] = pins["D6"]
+	pinMap.D[7] = pins["D7"]
+
+	c, err := NewGPIOController(pinMap)
+	if err != nil {
+		t.Fatalf("NewGPIOController: %v", err)
+	}
+	return c, pins
+}
+
+// TestGPIOControllerWriteIRNibblePacking checks that a 4-bit WriteIR sends the
+// high nibble then the low nibble across D4-D7, with RS held low throughout
+// and EN strobed once per nibble.
+func TestGPIOControllerWriteIRNibblePacking(t *testing.T) {
+	c, pins := newFakeGPIOController(t)
+	for _, p := range pins {
+		p.writes = nil // drop the init-time writes NewGPIOController issues
+	}
+
+	if err := c.WriteIR(0xAB); err != nil {
+		t.Fatalf("WriteIR: %v", err)
+	}
+
+	if !intsEqual(pins["D4"].writes, []int{0, 1}) {
+		t.Errorf("D4 writes = %v, want [0 1]", pins["D4"].writes)
+	}
+	if !intsEqual(pins["D5"].writes, []int{1, 1}) {
+		t.Errorf("D5 writes = %v, want [1 1]", pins["D5"].writes)
+	}
+	if !intsEqual(pins["D6"].writes, []int{0, 0}) {
+		t.Errorf("D6 writes = %v, want [0 0]", pins["D6"].writes)
+	}
+	if !intsEqual(pins["D7"].writes, []int{1, 1}) {
+		t.Errorf("D7 writes = %v, want [1 1]", pins["D7"].writes)
+	}
+	if !intsEqual(pins["EN"].writes, []int{1, 0, 1, 0}) {
+		t.Errorf("EN writes = %v, want [1 0 1 0]", pins["EN"].writes)
+	}
+	if !intsEqual(pins["RS"].writes, []int{0}) {
+		t.Errorf("RS writes = %v, want [0]", pins["RS"].writes)
+	}
+}
+
+// TestGPIOControllerWriteDataSetsRS checks that WriteData drives RS high once,
+// before either nibble is pulsed.
+func TestGPIOControllerWriteDataSetsRS(t *testing.T) {
+	c, pins := newFakeGPIOController(t)
+	for _, p := range pins {
+		p.writes = nil
+	}
+
+	if err := c.WriteData(0x00); err != nil {
+		t.Fatalf("WriteData: %v", err)
+	}
+
+	if !intsEqual(pins["RS"].writes, []int{1}) {
+		t.Errorf("RS writes = %v, want [1]", pins["RS"].writes)
+	}
+	if !intsEqual(pins["EN"].writes, []int{1, 0, 1, 0}) {
+		t.Errorf("EN writes = %v, want [1 0 1 0]", pins["EN"].writes)
+	}
+}
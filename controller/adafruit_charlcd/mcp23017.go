@@ -0,0 +1,242 @@
+package adafruit_charlcd
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/jjcinaz/embd"
+)
+
+// errReadNotSupported is returned by ReadIR/ReadData when a Controller's RW line
+// isn't wired up for reading.
+var errReadNotSupported = errors.New("charlcd: read not supported, RW is not wired")
+
+// MCP23017Controller implements Controller using an HD44780 hooked up through an
+// MCP23017 I2C-GPIO backpack.
+type MCP23017Controller struct {
+	I2C    embd.I2CBus
+	Addr   byte
+	PinMap I2CPinMap
+
+	// RGB holds the RGB backlight/button wiring when connected to Adafruit's RGB LCD
+	// Shield. It is nil for plain single-color backpacks.
+	RGB *RGBPinMap
+
+	// portBLatch holds non-LCD bits (the RGB backlight's blue LED) that must be
+	// preserved across every GPIOB write, since WriteIR/WriteData write the whole
+	// register.
+	portBLatch byte
+}
+
+// I2CPinMap represents a mapping between the pins on an I²C port expander and
+// the pins on the ADAFRUIT_CHARLCD controller.
+type I2CPinMap struct {
+	RS, RW, EN     byte
+	D4, D5, D6, D7 byte
+	Backlight      byte
+	BLPolarity     BacklightPolarity
+}
+
+// RGBPinMap extends I2CPinMap with the wiring used by Adafruit's RGB 16x2 LCD
+// Shield: three active-low backlight LED bits (two on GPIOA, one on GPIOB)
+// alongside the five GPIOA buttons read through ReadButtons.
+type RGBPinMap struct {
+	I2CPinMap
+	RedPin, GreenPin byte // GPIOA bits driving the red and green backlight LEDs
+	BluePin          byte // GPIOB bit driving the blue backlight LED
+}
+
+var (
+	// MCP230XXPinMap is the standard pin mapping for an MCP23017-based I²C backpack.
+	MCP230XXPinMap I2CPinMap = I2CPinMap{
+		RS: 7, RW: 6, EN: 5,
+		D4: 4, D5: 3, D6: 2, D7: 1,
+		Backlight:  0,
+		BLPolarity: Positive,
+	}
+
+	// RGBShieldPinMap is the pin mapping for Adafruit's RGB 16x2 LCD Shield, which
+	// wires the LCD data pins to GPIOB and the RGB backlight and buttons to GPIOA.
+	RGBShieldPinMap RGBPinMap = RGBPinMap{
+		I2CPinMap: I2CPinMap{
+			RS: 1, EN: 2,
+			D4: 3, D5: 4, D6: 5, D7: 6,
+			BLPolarity: Negative,
+		},
+		RedPin:   6,
+		GreenPin: 7,
+		BluePin:  0,
+	}
+)
+
+// initMCP23017 resets the bank/auto-increment configuration shared by every
+// MCP23017-based backpack.
+func initMCP23017(i2c embd.I2CBus, addr byte) {
+	_ = i2c.WriteByteToReg(addr, 0x05, 0x00) // IOCON.BANK = 0 if it was in BANK1 mode
+	_ = i2c.WriteByteToReg(addr, 0x0A, 0x20) // IOCON.BANK = 0, no auto-increment
+}
+
+// NewMCP23017Controller returns a new Controller based on an I²C bus.
+func NewMCP23017Controller(i2c embd.I2CBus, addr byte, pinMap I2CPinMap) *MCP23017Controller {
+	x := &MCP23017Controller{
+		I2C:    i2c,
+		Addr:   addr,
+		PinMap: pinMap,
+	}
+	initMCP23017(i2c, addr)
+	_ = x.I2C.WriteByteToReg(addr, 0x12, 0x40) // backlight off
+	_ = x.I2C.WriteByteToReg(addr, 0x00, 0x00) // all A-pins to output
+	_ = x.I2C.WriteByteToReg(addr, 0x01, 0x00) // all B-pins to output
+	return x
+}
+
+// NewRGBShieldController returns a new Controller for Adafruit's RGB LCD Shield.
+// Unlike NewMCP23017Controller, GPIOA is reconfigured as input with internal
+// pull-ups enabled on bits 0-4 for the shield's five buttons, leaving bits 6-7 as
+// outputs for the red and green backlight LEDs.
+func NewRGBShieldController(i2c embd.I2CBus, addr byte, pinMap RGBPinMap) *MCP23017Controller {
+	x := &MCP23017Controller{
+		I2C:    i2c,
+		Addr:   addr,
+		PinMap: pinMap.I2CPinMap,
+		RGB:    &pinMap,
+	}
+	initMCP23017(i2c, addr)
+	_ = x.I2C.WriteByteToReg(addr, 0x00, 0x1F) // GPIOA bits 0-4 input (buttons), 5-7 output (RGB LEDs)
+	_ = x.I2C.WriteByteToReg(addr, 0x0C, 0x1F) // enable internal pull-ups on GPIOA bits 0-4
+	_ = x.I2C.WriteByteToReg(addr, 0x01, 0x00) // all B-pins to output
+	_ = x.SetBacklightColor(false, false, false)
+	return x
+}
+
+// EightBitMode always returns false: the MCP23017 backpack only drives the 4 data
+// lines needed for 4-bit transfers.
+func (conn *MCP23017Controller) EightBitMode() bool { return false }
+
+// SetBacklight turns the optional backlight on or off. On an RGB shield
+// connection (RGB is non-nil), GPIOA bits 6/7 are the red/green backlight LEDs
+// rather than a dedicated backlight bit, so this forwards to SetBacklightColor
+// instead of writing register 0x12 directly, to avoid clobbering whatever
+// color SetBacklightColor last set.
+func (conn *MCP23017Controller) SetBacklight(on bool) error {
+	if conn.RGB != nil {
+		return conn.SetBacklightColor(on, on, on)
+	}
+	if on {
+		return conn.I2C.WriteByteToReg(conn.Addr, 0x12, 0x00) // backlight on
+	}
+	return conn.I2C.WriteByteToReg(conn.Addr, 0x12, 0x40) // backlight off
+}
+
+// SetBacklightColor sets the RGB backlight LEDs on Adafruit's RGB LCD Shield. On
+// connections without an RGB backlight wired up (RGB is nil), any of r, g, b being
+// true turns the single-color backlight on, and all false turns it off, matching
+// SetBacklight.
+func (conn *MCP23017Controller) SetBacklightColor(r, g, b bool) error {
+	if conn.RGB == nil {
+		return conn.SetBacklight(r || g || b)
+	}
+
+	var gpioa byte
+	if !r {
+		gpioa |= 0x01 << conn.RGB.RedPin
+	}
+	if !g {
+		gpioa |= 0x01 << conn.RGB.GreenPin
+	}
+	if err := conn.I2C.WriteByteToReg(conn.Addr, 0x12, gpioa); err != nil {
+		return err
+	}
+
+	if b {
+		conn.portBLatch &^= 0x01 << conn.RGB.BluePin
+	} else {
+		conn.portBLatch |= 0x01 << conn.RGB.BluePin
+	}
+	return conn.I2C.WriteByteToReg(conn.Addr, 0x13, conn.portBLatch)
+}
+
+// ReadButtons reads the five momentary pushbuttons wired to GPIOA on Adafruit's
+// RGB LCD Shield and returns the currently pressed buttons as a bitmask.
+// Connections without buttons wired up (RGB is nil) always read back a zero mask.
+func (conn *MCP23017Controller) ReadButtons() (ButtonMask, error) {
+	if conn.RGB == nil {
+		return 0, nil
+	}
+	v, err := conn.I2C.ReadByteFromReg(conn.Addr, 0x12)
+	if err != nil {
+		return 0, err
+	}
+	// Buttons pull their GPIOA bit low when pressed.
+	return ButtonMask(^v) & buttonMaskAll, nil
+}
+
+// WriteIR writes an instruction byte (RS low) to the bus.
+func (conn *MCP23017Controller) WriteIR(value byte) error {
+	return conn.write(false, value)
+}
+
+// WriteData writes a data byte (RS high) to the bus.
+func (conn *MCP23017Controller) WriteData(value byte) error {
+	return conn.write(true, value)
+}
+
+// ReadIR always returns an error: this Controller never drives the RW pin high,
+// so it only writes to the display.
+func (conn *MCP23017Controller) ReadIR() (byte, error) {
+	return 0, errReadNotSupported
+}
+
+// ReadData always returns an error: this Controller never drives the RW pin
+// high, so it only writes to the display.
+func (conn *MCP23017Controller) ReadData() (byte, error) {
+	return 0, errReadNotSupported
+}
+
+func (conn *MCP23017Controller) write(rs bool, data byte) error {
+	var instructionHigh byte = 0x00
+	instructionHigh |= ((data >> 4) & 0x01) << conn.PinMap.D4
+	instructionHigh |= ((data >> 5) & 0x01) << conn.PinMap.D5
+	instructionHigh |= ((data >> 6) & 0x01) << conn.PinMap.D6
+	instructionHigh |= ((data >> 7) & 0x01) << conn.PinMap.D7
+
+	var instructionLow byte = 0x00
+	instructionLow |= (data & 0x01) << conn.PinMap.D4
+	instructionLow |= ((data >> 1) & 0x01) << conn.PinMap.D5
+	instructionLow |= ((data >> 2) & 0x01) << conn.PinMap.D6
+	instructionLow |= ((data >> 3) & 0x01) << conn.PinMap.D7
+
+	instructions := []byte{instructionHigh, instructionLow}
+	for _, ins := range instructions {
+		if rs {
+			ins |= 0x01 << conn.PinMap.RS
+		}
+		glog.V(3).Infof("charlcd: writing to I2C: %#x", ins)
+		err := conn.pulseEnable(ins)
+		if err != nil {
+			return err
+		}
+	}
+	time.Sleep(writeDelay)
+	return nil
+}
+
+func (conn *MCP23017Controller) pulseEnable(data byte) error {
+	data |= conn.portBLatch
+	bytes := []byte{data, data | (0x01 << conn.PinMap.EN), data}
+	for _, b := range bytes {
+		time.Sleep(pulseDelay)
+		err := conn.I2C.WriteByteToReg(conn.Addr, 0x13, b)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the I²C connection.
+func (conn *MCP23017Controller) Close() error {
+	glog.V(2).Info("charlcd: closing I2C bus")
+	return conn.I2C.Close()
+}
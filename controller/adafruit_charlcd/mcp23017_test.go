@@ -0,0 +1,127 @@
+package adafruit_charlcd
+
+import "testing"
+
+// fakeI2CBus is a minimal embd.I2CBus stand-in recording every register write
+// so the MCP23017/PCF8574 bit-packing in write()/pulseEnable can be checked
+// without real hardware.
+type fakeI2CBus struct {
+	writes   []fakeI2CWrite
+	nextRead byte
+}
+
+type fakeI2CWrite struct {
+	addr byte
+	reg  byte
+	data byte
+}
+
+func (b *fakeI2CBus) WriteByteToReg(addr, reg, data byte) error {
+	b.writes = append(b.writes, fakeI2CWrite{addr, reg, data})
+	return nil
+}
+
+func (b *fakeI2CBus) ReadByteFromReg(addr, reg byte) (byte, error) {
+	return b.nextRead, nil
+}
+
+func (b *fakeI2CBus) WriteByte(addr, data byte) error {
+	b.writes = append(b.writes, fakeI2CWrite{addr, 0, data})
+	return nil
+}
+
+func (b *fakeI2CBus) Close() error { return nil }
+
+func regWrites(writes []fakeI2CWrite, reg byte) []byte {
+	var out []byte
+	for _, w := range writes {
+		if w.reg == reg {
+			out = append(out, w.data)
+		}
+	}
+	return out
+}
+
+func bytesEqual(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestMCP23017ControllerWriteIRBitPacking checks that WriteIR splits a byte
+// into high/low nibbles on D4-D7 and strobes EN, per MCP230XXPinMap.
+func TestMCP23017ControllerWriteIRBitPacking(t *testing.T) {
+	bus := &fakeI2CBus{}
+	conn := &MCP23017Controller{I2C: bus, Addr: 0x20, PinMap: MCP230XXPinMap}
+
+	if err := conn.WriteIR(0xAB); err != nil {
+		t.Fatalf("WriteIR: %v", err)
+	}
+
+	got := regWrites(bus.writes, 0x13)
+	want := []byte{0x0A, 0x2A, 0x0A, 0x1A, 0x3A, 0x1A}
+	if !bytesEqual(got, want) {
+		t.Errorf("GPIOB writes = %#v, want %#v", got, want)
+	}
+}
+
+// TestMCP23017ControllerWriteDataSetsRS checks that WriteData ORs in the RS bit
+// that WriteIR omits.
+func TestMCP23017ControllerWriteDataSetsRS(t *testing.T) {
+	bus := &fakeI2CBus{}
+	conn := &MCP23017Controller{I2C: bus, Addr: 0x20, PinMap: MCP230XXPinMap}
+
+	if err := conn.WriteData(0x00); err != nil {
+		t.Fatalf("WriteData: %v", err)
+	}
+
+	got := regWrites(bus.writes, 0x13)
+	want := []byte{0x80, 0xA0, 0x80, 0x80, 0xA0, 0x80}
+	if !bytesEqual(got, want) {
+		t.Errorf("GPIOB writes = %#v, want %#v", got, want)
+	}
+}
+
+// TestMCP23017ControllerSetBacklightRGBAware checks that SetBacklight on an RGB
+// shield connection forwards to SetBacklightColor instead of writing register
+// 0x12 directly, so it can't clobber a color SetBacklightColor already set.
+func TestMCP23017ControllerSetBacklightRGBAware(t *testing.T) {
+	bus := &fakeI2CBus{}
+	pinMap := RGBShieldPinMap
+	conn := &MCP23017Controller{I2C: bus, Addr: 0x20, PinMap: pinMap.I2CPinMap, RGB: &pinMap}
+
+	if err := conn.SetBacklightColor(true, false, false); err != nil {
+		t.Fatalf("SetBacklightColor: %v", err)
+	}
+	if err := conn.SetBacklight(false); err != nil {
+		t.Fatalf("SetBacklight(false): %v", err)
+	}
+
+	got := regWrites(bus.writes, 0x12)
+	want := []byte{0x80, 0xC0} // red-on/green-off, then red-off/green-off
+	if !bytesEqual(got, want) {
+		t.Errorf("GPIOA writes = %#v, want %#v", got, want)
+	}
+}
+
+// TestMCP23017ControllerReadButtons checks the active-low GPIOA bits are
+// inverted and masked down to the 5 button bits.
+func TestMCP23017ControllerReadButtons(t *testing.T) {
+	bus := &fakeI2CBus{nextRead: 0x1E} // bit0 (Select) pulled low, bits1-4 high
+	pinMap := RGBShieldPinMap
+	conn := &MCP23017Controller{I2C: bus, Addr: 0x20, PinMap: pinMap.I2CPinMap, RGB: &pinMap}
+
+	mask, err := conn.ReadButtons()
+	if err != nil {
+		t.Fatalf("ReadButtons: %v", err)
+	}
+	if mask != ButtonSelect {
+		t.Errorf("ReadButtons = %#x, want %#x (ButtonSelect)", mask, ButtonSelect)
+	}
+}
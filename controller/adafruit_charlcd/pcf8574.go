@@ -0,0 +1,145 @@
+package adafruit_charlcd
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/jjcinaz/embd"
+)
+
+// PCF8574Controller implements Controller using a PCF8574/PCF8574A I²C serial LCD
+// backpack. Unlike the MCP23017-based backpacks, the PCF8574 has no internal
+// register addressing: a single byte written to the device address sets all 8
+// P0-P7 outputs at once.
+type PCF8574Controller struct {
+	I2C    embd.I2CBus
+	Addr   byte
+	PinMap PCF8574PinMap
+
+	// backlight holds the current backlight bit (0 or 1<<PinMap.Backlight) that
+	// must be OR'd into every byte written, since a single byte sets all 8 pins.
+	backlight byte
+}
+
+// PCF8574PinMap represents a mapping between the P0-P7 pins on a PCF8574 I²C port
+// expander and the pins on the ADAFRUIT_CHARLCD controller.
+type PCF8574PinMap struct {
+	RS, RW, EN     byte
+	D4, D5, D6, D7 byte
+	Backlight      byte
+	BLPolarity     BacklightPolarity
+}
+
+// YwRobotPinMap is the standard pin mapping used by the ubiquitous "YwRobot/Sainsmart"
+// PCF8574 I²C serial LCD backpack found on most cheap HD44780 modules.
+var YwRobotPinMap = PCF8574PinMap{
+	RS: 0, RW: 1, EN: 2,
+	Backlight: 3,
+	D4: 4, D5: 5, D6: 6, D7: 7,
+	BLPolarity: Positive,
+}
+
+// NewPCF8574Controller returns a new Controller based on a PCF8574 I²C backpack.
+func NewPCF8574Controller(i2c embd.I2CBus, addr byte, pinMap PCF8574PinMap) *PCF8574Controller {
+	x := &PCF8574Controller{
+		I2C:    i2c,
+		Addr:   addr,
+		PinMap: pinMap,
+	}
+	_ = x.SetBacklight(false)
+	return x
+}
+
+// EightBitMode always returns false: the PCF8574 backpack only drives the 4 data
+// lines needed for 4-bit transfers.
+func (conn *PCF8574Controller) EightBitMode() bool { return false }
+
+// SetBacklight turns the optional backlight on or off.
+func (conn *PCF8574Controller) SetBacklight(on bool) error {
+	if on == (conn.PinMap.BLPolarity == Positive) {
+		conn.backlight = 0x01 << conn.PinMap.Backlight
+	} else {
+		conn.backlight = 0x00
+	}
+	return conn.I2C.WriteByte(conn.Addr, conn.backlight)
+}
+
+// SetBacklightColor treats any of r, g, b being true as on and all false as off,
+// since the PCF8574 backpack only drives a single-color backlight.
+func (conn *PCF8574Controller) SetBacklightColor(r, g, b bool) error {
+	return conn.SetBacklight(r || g || b)
+}
+
+// ReadButtons always returns a zero mask: the PCF8574 backpack has no buttons wired up.
+func (conn *PCF8574Controller) ReadButtons() (ButtonMask, error) {
+	return 0, nil
+}
+
+// WriteIR writes an instruction byte (RS low) to the bus.
+func (conn *PCF8574Controller) WriteIR(value byte) error {
+	return conn.write(false, value)
+}
+
+// WriteData writes a data byte (RS high) to the bus.
+func (conn *PCF8574Controller) WriteData(value byte) error {
+	return conn.write(true, value)
+}
+
+// ReadIR always returns an error: this Controller never drives the RW pin high,
+// so it only writes to the display.
+func (conn *PCF8574Controller) ReadIR() (byte, error) {
+	return 0, errReadNotSupported
+}
+
+// ReadData always returns an error: this Controller never drives the RW pin
+// high, so it only writes to the display.
+func (conn *PCF8574Controller) ReadData() (byte, error) {
+	return 0, errReadNotSupported
+}
+
+func (conn *PCF8574Controller) write(rs bool, data byte) error {
+	var nibbleHigh byte = 0x00
+	nibbleHigh |= ((data >> 4) & 0x01) << conn.PinMap.D4
+	nibbleHigh |= ((data >> 5) & 0x01) << conn.PinMap.D5
+	nibbleHigh |= ((data >> 6) & 0x01) << conn.PinMap.D6
+	nibbleHigh |= ((data >> 7) & 0x01) << conn.PinMap.D7
+
+	var nibbleLow byte = 0x00
+	nibbleLow |= (data & 0x01) << conn.PinMap.D4
+	nibbleLow |= ((data >> 1) & 0x01) << conn.PinMap.D5
+	nibbleLow |= ((data >> 2) & 0x01) << conn.PinMap.D6
+	nibbleLow |= ((data >> 3) & 0x01) << conn.PinMap.D7
+
+	nibbles := []byte{nibbleHigh, nibbleLow}
+	for _, n := range nibbles {
+		b := n | conn.backlight
+		if rs {
+			b |= 0x01 << conn.PinMap.RS
+		}
+		glog.V(3).Infof("charlcd: writing to I2C: %#x", b)
+		err := conn.pulseEnable(b)
+		if err != nil {
+			return err
+		}
+	}
+	time.Sleep(writeDelay)
+	return nil
+}
+
+func (conn *PCF8574Controller) pulseEnable(data byte) error {
+	bytes := []byte{data, data | (0x01 << conn.PinMap.EN), data}
+	for _, b := range bytes {
+		time.Sleep(pulseDelay)
+		err := conn.I2C.WriteByte(conn.Addr, b)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the I²C connection.
+func (conn *PCF8574Controller) Close() error {
+	glog.V(2).Info("charlcd: closing I2C bus")
+	return conn.I2C.Close()
+}
@@ -0,0 +1,77 @@
+package adafruit_charlcd
+
+import "testing"
+
+// TestPCF8574ControllerWriteIRBitPacking checks that WriteIR ORs the D4-D7
+// nibble, RS and the latched backlight bit into a single byte per nibble,
+// since the PCF8574 has no register addressing.
+func TestPCF8574ControllerWriteIRBitPacking(t *testing.T) {
+	bus := &fakeI2CBus{}
+	conn := NewPCF8574Controller(bus, 0x27, YwRobotPinMap)
+	bus.writes = nil // drop the backlight-off write NewPCF8574Controller issues
+
+	if err := conn.WriteIR(0xAB); err != nil {
+		t.Fatalf("WriteIR: %v", err)
+	}
+
+	got := regWrites(bus.writes, 0)
+	want := []byte{0xA0, 0xA4, 0xA0, 0xB0, 0xB4, 0xB0}
+	if !bytesEqual(got, want) {
+		t.Errorf("bus writes = %#v, want %#v", got, want)
+	}
+}
+
+// TestPCF8574ControllerWriteDataSetsRS checks that WriteData ORs in the RS bit
+// that WriteIR omits.
+func TestPCF8574ControllerWriteDataSetsRS(t *testing.T) {
+	bus := &fakeI2CBus{}
+	conn := NewPCF8574Controller(bus, 0x27, YwRobotPinMap)
+	bus.writes = nil
+
+	if err := conn.WriteData(0x00); err != nil {
+		t.Fatalf("WriteData: %v", err)
+	}
+
+	got := regWrites(bus.writes, 0)
+	want := []byte{0x01, 0x05, 0x01, 0x01, 0x05, 0x01}
+	if !bytesEqual(got, want) {
+		t.Errorf("bus writes = %#v, want %#v", got, want)
+	}
+}
+
+// TestPCF8574ControllerSetBacklightPolarity checks both active-high
+// (YwRobotPinMap) and active-low backlight polarities latch the right bit.
+func TestPCF8574ControllerSetBacklightPolarity(t *testing.T) {
+	bus := &fakeI2CBus{}
+	conn := NewPCF8574Controller(bus, 0x27, YwRobotPinMap) // Positive polarity, Backlight=3
+
+	if err := conn.SetBacklight(true); err != nil {
+		t.Fatalf("SetBacklight(true): %v", err)
+	}
+	if conn.backlight != 0x08 {
+		t.Errorf("backlight latch = %#x, want 0x08", conn.backlight)
+	}
+
+	if err := conn.SetBacklight(false); err != nil {
+		t.Fatalf("SetBacklight(false): %v", err)
+	}
+	if conn.backlight != 0x00 {
+		t.Errorf("backlight latch = %#x, want 0x00", conn.backlight)
+	}
+
+	negMap := YwRobotPinMap
+	negMap.BLPolarity = Negative
+	negConn := NewPCF8574Controller(bus, 0x27, negMap)
+	if err := negConn.SetBacklight(true); err != nil {
+		t.Fatalf("SetBacklight(true) negative polarity: %v", err)
+	}
+	if negConn.backlight != 0x00 {
+		t.Errorf("negative-polarity backlight latch on = %#x, want 0x00", negConn.backlight)
+	}
+	if err := negConn.SetBacklight(false); err != nil {
+		t.Fatalf("SetBacklight(false) negative polarity: %v", err)
+	}
+	if negConn.backlight != 0x08 {
+		t.Errorf("negative-polarity backlight latch off = %#x, want 0x08", negConn.backlight)
+	}
+}
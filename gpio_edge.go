@@ -0,0 +1,157 @@
+package embd
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/golang/glog"
+)
+
+// Edge selects which signal transition a watched pin's sysfs "edge" file is
+// armed for, mirroring the values the kernel GPIO sysfs interface accepts.
+type Edge string
+
+const (
+	EdgeNone    Edge = "none"
+	EdgeRising  Edge = "rising"
+	EdgeFalling Edge = "falling"
+	EdgeBoth    Edge = "both"
+)
+
+// watch holds the goroutine and file descriptors backing one digitalPin's
+// Watch call, so StopWatching can tear them down cleanly.
+type watch struct {
+	valFile *os.File
+	epfd    int
+	stopR   *os.File
+	stopW   *os.File
+	done    chan struct{}
+}
+
+// Watch arms edge-triggered interrupts on the pin: it writes edge to the
+// pin's sysfs "edge" file, then starts a goroutine that epolls the "value"
+// file for EPOLLPRI|EPOLLERR (the way the kernel reports GPIO value changes
+// to userspace) and, on each event, re-reads the value file and invokes
+// handler with the pin. Any previous Watch on this pin is stopped first.
+func (d *digitalPin) Watch(edge Edge, handler func(DigitalPin)) error {
+	if err := d.StopWatching(); err != nil {
+		return err
+	}
+
+	edgePath := fmt.Sprintf("/sys/class/gpio/gpio%d/edge", d.n)
+	if err := os.WriteFile(edgePath, []byte(edge), 0644); err != nil {
+		return fmt.Errorf("gpio: could not set edge on pin %v: %v", d.n, err)
+	}
+
+	valPath := fmt.Sprintf("/sys/class/gpio/gpio%d/value", d.n)
+	valFile, err := os.OpenFile(valPath, os.O_RDONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("gpio: could not open value file for pin %v: %v", d.n, err)
+	}
+	// sysfs reports POLLPRI on a value file that has not been read since it was
+	// opened; read it once so only real edges wake epoll_wait.
+	if _, err := valFile.Read(make([]byte, 1)); err != nil {
+		valFile.Close()
+		return fmt.Errorf("gpio: could not read value file for pin %v: %v", d.n, err)
+	}
+
+	epfd, err := syscall.EpollCreate1(0)
+	if err != nil {
+		valFile.Close()
+		return fmt.Errorf("gpio: epoll_create1: %v", err)
+	}
+
+	valFd := int(valFile.Fd())
+	if err := syscall.EpollCtl(epfd, syscall.EPOLL_CTL_ADD, valFd, &syscall.EpollEvent{
+		Events: syscall.EPOLLPRI | syscall.EPOLLERR,
+		Fd:     int32(valFd),
+	}); err != nil {
+		syscall.Close(epfd)
+		valFile.Close()
+		return fmt.Errorf("gpio: epoll_ctl add value fd: %v", err)
+	}
+
+	// stopR/stopW is the usual self-pipe trick: StopWatching writes a byte to
+	// stopW to wake the blocked epoll_wait and end the goroutine.
+	stopR, stopW, err := os.Pipe()
+	if err != nil {
+		syscall.Close(epfd)
+		valFile.Close()
+		return err
+	}
+	stopFd := int(stopR.Fd())
+	if err := syscall.EpollCtl(epfd, syscall.EPOLL_CTL_ADD, stopFd, &syscall.EpollEvent{
+		Events: syscall.EPOLLIN,
+		Fd:     int32(stopFd),
+	}); err != nil {
+		stopR.Close()
+		stopW.Close()
+		syscall.Close(epfd)
+		valFile.Close()
+		return fmt.Errorf("gpio: epoll_ctl add stop fd: %v", err)
+	}
+
+	w := &watch{
+		valFile: valFile,
+		epfd:    epfd,
+		stopR:   stopR,
+		stopW:   stopW,
+		done:    make(chan struct{}),
+	}
+	d.watch = w
+
+	go d.watchLoop(w, valFd, stopFd, handler)
+
+	return nil
+}
+
+func (d *digitalPin) watchLoop(w *watch, valFd, stopFd int, handler func(DigitalPin)) {
+	defer close(w.done)
+	events := make([]syscall.EpollEvent, 2)
+	buf := make([]byte, 1)
+	for {
+		n, err := syscall.EpollWait(w.epfd, events, -1)
+		if err != nil {
+			if err == syscall.EINTR {
+				continue
+			}
+			glog.Errorf("gpio: epoll_wait on pin %v: %v", d.n, err)
+			return
+		}
+		for i := 0; i < n; i++ {
+			if int(events[i].Fd) == stopFd {
+				return
+			}
+			if _, err := w.valFile.Seek(0, 0); err != nil {
+				glog.Errorf("gpio: seek value file for pin %v: %v", d.n, err)
+				continue
+			}
+			if _, err := w.valFile.Read(buf); err != nil {
+				glog.Errorf("gpio: read value file for pin %v: %v", d.n, err)
+				continue
+			}
+			handler(d)
+		}
+	}
+}
+
+// StopWatching cancels a previous Watch call, tearing down its goroutine and
+// epoll fd. It is a no-op if the pin isn't being watched.
+func (d *digitalPin) StopWatching() error {
+	if d.watch == nil {
+		return nil
+	}
+	w := d.watch
+	d.watch = nil
+
+	if _, err := w.stopW.Write([]byte{0}); err != nil {
+		return err
+	}
+	<-w.done
+
+	w.stopR.Close()
+	w.stopW.Close()
+	syscall.Close(w.epfd)
+	return w.valFile.Close()
+}
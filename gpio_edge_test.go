@@ -0,0 +1,74 @@
+package embd
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/jjcinaz/embd/host/all"
+)
+
+// TestDigitalPinWatch is an integration test: it requires two GPIO pins wired
+// together on real hardware, one driving the other. Set EMBD_TEST_OUT_PIN and
+// EMBD_TEST_IN_PIN to the pin identifiers (as recognized by the host's
+// PinMap) before running; it is skipped otherwise.
+func TestDigitalPinWatch(t *testing.T) {
+	outKey := os.Getenv("EMBD_TEST_OUT_PIN")
+	inKey := os.Getenv("EMBD_TEST_IN_PIN")
+	if outKey == "" || inKey == "" {
+		t.Skip("set EMBD_TEST_OUT_PIN and EMBD_TEST_IN_PIN to two GPIO pins wired together to run this test")
+	}
+
+	if err := InitGPIO(); err != nil {
+		t.Fatalf("InitGPIO: %v", err)
+	}
+	defer CloseGPIO()
+
+	outPin, err := NewDigitalPin(outKey)
+	if err != nil {
+		t.Fatalf("NewDigitalPin(%q): %v", outKey, err)
+	}
+	defer outPin.Close()
+	if err := outPin.SetDirection(Out); err != nil {
+		t.Fatalf("SetDirection(out): %v", err)
+	}
+	if err := outPin.Write(Low); err != nil {
+		t.Fatalf("Write(out, Low): %v", err)
+	}
+
+	inPin, err := NewDigitalPin(inKey)
+	if err != nil {
+		t.Fatalf("NewDigitalPin(%q): %v", inKey, err)
+	}
+	defer inPin.Close()
+	if err := inPin.SetDirection(In); err != nil {
+		t.Fatalf("SetDirection(in): %v", err)
+	}
+
+	fired := make(chan int, 1)
+	if err := inPin.Watch(EdgeRising, func(p DigitalPin) {
+		v, err := p.Read()
+		if err != nil {
+			t.Errorf("Read in handler: %v", err)
+			return
+		}
+		fired <- v
+	}); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	defer inPin.StopWatching()
+
+	time.Sleep(100 * time.Millisecond) // let the edge sysfs file + goroutine settle
+	if err := outPin.Write(High); err != nil {
+		t.Fatalf("Write(out, High): %v", err)
+	}
+
+	select {
+	case v := <-fired:
+		if v != 1 {
+			t.Errorf("handler read %d, want 1", v)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for rising-edge interrupt")
+	}
+}
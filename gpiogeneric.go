@@ -101,11 +101,17 @@ func (io *gpioDriver) DigitalPin(key interface{}) (DigitalPin, error) {
 }
 
 func (io *gpioDriver) Close() error {
+	var firstErr error
 	for _, p := range io.initializedPins {
-		if err := p.Close(); err != nil {
-			return err
+		if dp, ok := p.(*digitalPin); ok {
+			if err := dp.StopWatching(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		if err := p.Close(); err != nil && firstErr == nil {
+			firstErr = err
 		}
 	}
 
-	return nil
+	return firstErr
 }
\ No newline at end of file